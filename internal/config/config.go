@@ -2,88 +2,103 @@ package config
 
 import (
 	"os"
-	"strconv"
 	"time"
 )
 
-// Config holds all application configuration
+// Config holds all application configuration. Fields carry an `env` tag
+// naming the environment variable / config-file key that populates them, a
+// `default` tag with their zero-config value, an `immutable:"true"` tag for
+// fields that cannot change via Reload (see Manager), and optionally a
+// `validate:"..."` tag checked by Validate (see validateFields in loader.go).
 type Config struct {
 	// HTTP Server
-	HTTPAddr string
+	HTTPAddr          string        `env:"HTTP_ADDR" default:":8080" immutable:"true"`
+	HTTPShutdownGrace time.Duration `env:"HTTP_SHUTDOWN_GRACE" default:"30s"`
 
 	// Database
-	DatabaseDSN string
+	DatabaseDSN string `env:"DB_DSN" default:"postgres://localhost/realitycheck?sslmode=disable" immutable:"true" secret:"true"`
 
 	// OpenAI
-	OpenAIAPIKey string
-	OpenAIRPS    int
-	OpenAIBurst  int
+	OpenAIAPIKey string `env:"OPENAI_API_KEY" default:"" secret:"true" validate:"required"`
+	OpenAIRPS    int    `env:"OPENAI_RPS" default:"2"`
+	OpenAIBurst  int    `env:"OPENAI_BURST" default:"4"`
 
 	// Cache
-	CacheLRUSize int
-	CacheTTL     time.Duration
-	CacheDir     string
+	CacheLRUSize int           `env:"CACHE_LRU_SIZE" default:"4096"`
+	CacheTTL     time.Duration `env:"CACHE_TTL" default:"24h"`
+	CacheDir     string        `env:"CACHE_DIR" default:"/var/lib/realitycheck/cache"`
 
 	// Analysis
-	MaxEvidencePerQuery int
-	MaxQueries          int
-	AnalysisTimeout     time.Duration
+	MaxEvidencePerQuery int           `env:"MAX_EVIDENCE_PER_QUERY" default:"10"`
+	MaxQueries          int           `env:"MAX_QUERIES" default:"20"`
+	AnalysisTimeout     time.Duration `env:"ANALYSIS_TIMEOUT" default:"60s"`
 
 	// Security
-	BearerToken string
+	BearerToken string `env:"BEARER_TOKEN" default:"" secret:"true"`
+
+	// AuthMode selects how incoming requests are authenticated: "none",
+	// "bearer" (static BearerToken), or "oidc" (OIDCIssuer JWKS).
+	AuthMode        string        `env:"AUTH_MODE" default:"none" validate:"oneof=none bearer oidc"`
+	OIDCIssuer      string        `env:"OIDC_ISSUER" default:""`
+	OIDCAudience    string        `env:"OIDC_AUDIENCE" default:""`
+	OIDCJWKSRefresh time.Duration `env:"OIDC_JWKS_REFRESH" default:"15m"`
+
+	// HTTP admission control. HTTPLongRunningRE is compiled by
+	// (*Config).LimitsConfig; see limits.Config / (*Config).LimitsConfig.
+	HTTPMaxInFlight    int     `env:"HTTP_MAX_IN_FLIGHT" default:"64"`
+	HTTPPerClientRPS   float64 `env:"HTTP_PER_CLIENT_RPS" default:"5"`
+	HTTPPerClientBurst int     `env:"HTTP_PER_CLIENT_BURST" default:"10"`
+	HTTPLongRunningRE  string  `env:"HTTP_LONG_RUNNING_RE" default:"^/v1/(analyze|stream)"`
+
+	// CORS. Origins/methods/headers are comma-separated; an origin entry
+	// prefixed "regex:" is compiled as a regular expression. See
+	// httpx.CORSConfig / (*Config).CORSConfig.
+	CORSAllowedOrigins   string        `env:"CORS_ALLOWED_ORIGINS" default:""`
+	CORSAllowedMethods   string        `env:"CORS_ALLOWED_METHODS" default:"GET,POST,PUT,DELETE,OPTIONS"`
+	CORSAllowedHeaders   string        `env:"CORS_ALLOWED_HEADERS" default:"Content-Type,Authorization"`
+	CORSExposedHeaders   string        `env:"CORS_EXPOSED_HEADERS" default:""`
+	CORSAllowCredentials bool          `env:"CORS_ALLOW_CREDENTIALS" default:"false"`
+	CORSMaxAge           time.Duration `env:"CORS_MAX_AGE" default:"10m"`
 
 	// Telemetry
-	LogLevel string
-}
+	LogLevel  string `env:"LOG_LEVEL" default:"info"`
+	LogFormat string `env:"LOG_FORMAT" default:"text"` // "text" or "json"
 
-// Load reads configuration from environment variables with defaults
-func Load() *Config {
-	return &Config{
-		HTTPAddr:            getEnv("HTTP_ADDR", ":8080"),
-		DatabaseDSN:         getEnv("DB_DSN", "postgres://localhost/realitycheck?sslmode=disable"),
-		OpenAIAPIKey:        getEnv("OPENAI_API_KEY", ""),
-		OpenAIRPS:           getEnvInt("OPENAI_RPS", 2),
-		OpenAIBurst:         getEnvInt("OPENAI_BURST", 4),
-		CacheLRUSize:        getEnvInt("CACHE_LRU_SIZE", 4096),
-		CacheTTL:            getEnvDuration("CACHE_TTL", 24*time.Hour),
-		CacheDir:            getEnv("CACHE_DIR", "/var/lib/realitycheck/cache"),
-		MaxEvidencePerQuery: getEnvInt("MAX_EVIDENCE_PER_QUERY", 10),
-		MaxQueries:          getEnvInt("MAX_QUERIES", 20),
-		AnalysisTimeout:     getEnvDuration("ANALYSIS_TIMEOUT", 60*time.Second),
-		BearerToken:         getEnv("BEARER_TOKEN", ""),
-		LogLevel:            getEnv("LOG_LEVEL", "info"),
-	}
+	OTELExporter    string `env:"OTEL_EXPORTER" default:"none"` // "none", "otlp", or "stdout"
+	OTELEndpoint    string `env:"OTEL_ENDPOINT" default:""`
+	OTELServiceName string `env:"OTEL_SERVICE_NAME" default:"realitycheck" immutable:"true"`
 }
 
-// Validate checks if required configuration is present
-func (c *Config) Validate() error {
-	if c.OpenAIAPIKey == "" {
-		return ErrMissingOpenAIKey
-	}
-	return nil
-}
+// Load reads configuration from environment variables with defaults.
+//
+// Deprecated: use LoadLayered, which also merges a config file and CLI flags
+// and resolves scheme://ref secret references (env://, file://, ...) in
+// fields tagged `secret:"true"`. Load remains for callers that only ever
+// need the env-var behavior.
+func Load() *Config {
+	cfg := defaultConfig()
+	applyEnv(cfg)
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+	// Preserve the pre-OIDC behavior: a deployment that only ever set
+	// BEARER_TOKEN keeps authenticating the same way without also having to
+	// set AUTH_MODE.
+	if cfg.AuthMode == "none" && cfg.BearerToken != "" && os.Getenv("AUTH_MODE") == "" {
+		cfg.AuthMode = "bearer"
 	}
-	return defaultValue
+	return cfg
 }
 
-func getEnvInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if parsed, err := strconv.Atoi(value); err == nil {
-			return parsed
-		}
+// Validate checks required configuration is present and constrained fields
+// hold an allowed value, via the `validate:"..."` struct tag (validateFields
+// in loader.go). The one check that isn't tag-driven is OIDCIssuer/
+// OIDCAudience: "required only when AuthMode is oidc" is a cross-field rule
+// that doesn't fit a flat per-field tag.
+func (c *Config) Validate() error {
+	if err := validateFields(c); err != nil {
+		return err
 	}
-	return defaultValue
-}
-
-func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
-	if value := os.Getenv(key); value != "" {
-		if parsed, err := time.ParseDuration(value); err == nil {
-			return parsed
-		}
+	if c.AuthMode == "oidc" && (c.OIDCIssuer == "" || c.OIDCAudience == "") {
+		return ErrMissingOIDCConfig
 	}
-	return defaultValue
+	return nil
 }
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateRequiredField(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.OpenAIAPIKey = ""
+
+	if err := cfg.Validate(); !errors.Is(err, ErrMissingOpenAIKey) {
+		t.Fatalf("Validate() = %v, want %v", err, ErrMissingOpenAIKey)
+	}
+
+	cfg.OpenAIAPIKey = "sk-test"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() = %v, want nil once OpenAIAPIKey is set", err)
+	}
+}
+
+func TestValidateOneOf(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.OpenAIAPIKey = "sk-test"
+	cfg.AuthMode = "not-a-real-mode"
+
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("Validate() = nil, want an error for an AuthMode outside its oneof set")
+	}
+
+	cfg.AuthMode = "bearer"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() = %v, want nil for an allowed AuthMode", err)
+	}
+}
+
+func TestValidateOIDCRequiresIssuerAndAudience(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.OpenAIAPIKey = "sk-test"
+	cfg.AuthMode = "oidc"
+
+	if err := cfg.Validate(); !errors.Is(err, ErrMissingOIDCConfig) {
+		t.Fatalf("Validate() = %v, want %v", err, ErrMissingOIDCConfig)
+	}
+
+	cfg.OIDCIssuer = "https://issuer.example.com"
+	cfg.OIDCAudience = "aud"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() = %v, want nil once OIDCIssuer/OIDCAudience are set", err)
+	}
+}
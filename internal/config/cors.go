@@ -0,0 +1,33 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/TimAnthonyAlexander/realitycheck/pkg/httpx"
+)
+
+// CORSConfig builds an httpx.CORSConfig from c's CORS_* fields.
+func (c *Config) CORSConfig() (*httpx.CORSConfig, error) {
+	return httpx.NewCORSConfig(httpx.CORSConfig{
+		AllowedOrigins:   splitCSV(c.CORSAllowedOrigins),
+		AllowedMethods:   splitCSV(c.CORSAllowedMethods),
+		AllowedHeaders:   splitCSV(c.CORSAllowedHeaders),
+		ExposedHeaders:   splitCSV(c.CORSExposedHeaders),
+		AllowCredentials: c.CORSAllowCredentials,
+		MaxAge:           c.CORSMaxAge,
+	})
+}
+
+func splitCSV(s string) []string {
+	if s == "" {
+		return nil
+	}
+	parts := strings.Split(s, ",")
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -0,0 +1,12 @@
+package config
+
+import "errors"
+
+var (
+	// ErrMissingOpenAIKey is returned by Validate when OpenAIAPIKey is unset.
+	ErrMissingOpenAIKey = errors.New("config: OPENAI_API_KEY is required")
+
+	// ErrMissingOIDCConfig is returned by Validate when AuthMode is "oidc"
+	// but OIDCIssuer or OIDCAudience is unset.
+	ErrMissingOIDCConfig = errors.New("config: OIDC_ISSUER and OIDC_AUDIENCE are required when AUTH_MODE=oidc")
+)
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/TimAnthonyAlexander/realitycheck/pkg/httpx/limits"
+)
+
+// LimitsConfig builds a limits.Config from c's HTTP_* admission-control
+// fields, compiling HTTPLongRunningRE so a bad regex is caught at config
+// load time instead of wherever a caller eventually calls regexp.MustCompile
+// on it. An empty HTTPLongRunningRE leaves LongRunningPath nil, matching no
+// paths.
+func (c *Config) LimitsConfig() (*limits.Config, error) {
+	var longRunning *regexp.Regexp
+	if c.HTTPLongRunningRE != "" {
+		re, err := regexp.Compile(c.HTTPLongRunningRE)
+		if err != nil {
+			return nil, fmt.Errorf("config: compile HTTP_LONG_RUNNING_RE %q: %w", c.HTTPLongRunningRE, err)
+		}
+		longRunning = re
+	}
+
+	return &limits.Config{
+		MaxInFlight:     c.HTTPMaxInFlight,
+		PerClientRPS:    c.HTTPPerClientRPS,
+		PerClientBurst:  c.HTTPPerClientBurst,
+		LongRunningPath: longRunning,
+	}, nil
+}
@@ -0,0 +1,44 @@
+package config
+
+import "testing"
+
+func TestLimitsConfigCompilesLongRunningRE(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.HTTPLongRunningRE = `^/v1/(analyze|stream)`
+
+	lc, err := cfg.LimitsConfig()
+	if err != nil {
+		t.Fatalf("LimitsConfig: %v", err)
+	}
+	if lc.LongRunningPath == nil {
+		t.Fatal("LongRunningPath is nil, want a compiled regexp")
+	}
+	if !lc.LongRunningPath.MatchString("/v1/analyze") {
+		t.Error("LongRunningPath doesn't match /v1/analyze")
+	}
+	if lc.MaxInFlight != cfg.HTTPMaxInFlight {
+		t.Errorf("MaxInFlight = %d, want %d", lc.MaxInFlight, cfg.HTTPMaxInFlight)
+	}
+}
+
+func TestLimitsConfigEmptyLongRunningRE(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.HTTPLongRunningRE = ""
+
+	lc, err := cfg.LimitsConfig()
+	if err != nil {
+		t.Fatalf("LimitsConfig: %v", err)
+	}
+	if lc.LongRunningPath != nil {
+		t.Errorf("LongRunningPath = %v, want nil for an empty HTTPLongRunningRE", lc.LongRunningPath)
+	}
+}
+
+func TestLimitsConfigInvalidRegex(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.HTTPLongRunningRE = "("
+
+	if _, err := cfg.LimitsConfig(); err == nil {
+		t.Fatal("LimitsConfig: expected an error for an invalid regex, got nil")
+	}
+}
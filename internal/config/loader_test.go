@@ -0,0 +1,112 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeYAMLConfig(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoadLayeredPrecedence(t *testing.T) {
+	path := writeYAMLConfig(t, "cache_lru_size: 100\n")
+
+	t.Run("defaults only", func(t *testing.T) {
+		cfg, err := LoadLayered(nil)
+		if err != nil {
+			t.Fatalf("LoadLayered: %v", err)
+		}
+		if cfg.CacheLRUSize != 4096 {
+			t.Errorf("CacheLRUSize = %d, want default 4096", cfg.CacheLRUSize)
+		}
+	})
+
+	t.Run("file overrides default", func(t *testing.T) {
+		cfg, err := LoadLayered([]string{"--config", path})
+		if err != nil {
+			t.Fatalf("LoadLayered: %v", err)
+		}
+		if cfg.CacheLRUSize != 100 {
+			t.Errorf("CacheLRUSize = %d, want file value 100", cfg.CacheLRUSize)
+		}
+	})
+
+	t.Run("env overrides file", func(t *testing.T) {
+		t.Setenv("CACHE_LRU_SIZE", "200")
+		cfg, err := LoadLayered([]string{"--config", path})
+		if err != nil {
+			t.Fatalf("LoadLayered: %v", err)
+		}
+		if cfg.CacheLRUSize != 200 {
+			t.Errorf("CacheLRUSize = %d, want env value 200", cfg.CacheLRUSize)
+		}
+	})
+
+	t.Run("flag overrides env", func(t *testing.T) {
+		t.Setenv("CACHE_LRU_SIZE", "200")
+		cfg, err := LoadLayered([]string{"--config", path, "--cache-lru-size", "300"})
+		if err != nil {
+			t.Fatalf("LoadLayered: %v", err)
+		}
+		if cfg.CacheLRUSize != 300 {
+			t.Errorf("CacheLRUSize = %d, want flag value 300", cfg.CacheLRUSize)
+		}
+	})
+}
+
+func TestLoadLayeredBearerTokenImpliesAuthMode(t *testing.T) {
+	t.Setenv("BEARER_TOKEN", "secret-token")
+
+	cfg, err := LoadLayered(nil)
+	if err != nil {
+		t.Fatalf("LoadLayered: %v", err)
+	}
+	if cfg.AuthMode != "bearer" {
+		t.Errorf("AuthMode = %q, want %q when BEARER_TOKEN is set without AUTH_MODE", cfg.AuthMode, "bearer")
+	}
+}
+
+func TestSetFieldValueDurationVsPlainInt(t *testing.T) {
+	cfg := defaultConfig()
+
+	if err := setFields(cfg, func(envKey string) (string, bool) {
+		switch envKey {
+		case "CACHE_TTL":
+			return "2h", true
+		case "CACHE_LRU_SIZE":
+			return "128", true
+		default:
+			return "", false
+		}
+	}); err != nil {
+		t.Fatalf("setFields: %v", err)
+	}
+
+	if cfg.CacheTTL != 2*time.Hour {
+		t.Errorf("CacheTTL = %s, want 2h", cfg.CacheTTL)
+	}
+	if cfg.CacheLRUSize != 128 {
+		t.Errorf("CacheLRUSize = %d, want 128", cfg.CacheLRUSize)
+	}
+}
+
+func TestResolveSecretsAppliesDuringLoad(t *testing.T) {
+	t.Setenv("REAL_OPENAI_KEY", "sk-from-env")
+	t.Setenv("OPENAI_API_KEY", "env://REAL_OPENAI_KEY")
+
+	cfg, err := LoadLayered(nil)
+	if err != nil {
+		t.Fatalf("LoadLayered: %v", err)
+	}
+	if cfg.OpenAIAPIKey != "sk-from-env" {
+		t.Errorf("OpenAIAPIKey = %q, want resolved value %q", cfg.OpenAIAPIKey, "sk-from-env")
+	}
+}
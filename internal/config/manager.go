@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// Manager owns the current Config and notifies subscribers when Reload
+// produces a new one. It exists so tunables like OpenAIRPS, CacheTTL,
+// MaxEvidencePerQuery, and LogLevel can change without a restart, while
+// fields tagged `immutable:"true"` (HTTPAddr, DatabaseDSN, ...) are rejected
+// if a reload tries to change them.
+type Manager struct {
+	args []string
+
+	mu          sync.RWMutex
+	current     *Config
+	subscribers []func(*Config)
+}
+
+// NewManager performs an initial LoadLayered(args) and returns a Manager
+// wrapping the result.
+func NewManager(args []string) (*Manager, error) {
+	cfg, err := LoadLayered(args)
+	if err != nil {
+		return nil, err
+	}
+	return &Manager{args: args, current: cfg}, nil
+}
+
+// Current returns the most recently loaded Config.
+func (m *Manager) Current() *Config {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.current
+}
+
+// Subscribe registers fn to be called with the new Config after every
+// successful Reload. fn is not called for the initial config.
+func (m *Manager) Subscribe(fn func(*Config)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subscribers = append(m.subscribers, fn)
+}
+
+// Reload re-runs LoadLayered and, if no immutable field changed, swaps it in
+// as Current and notifies subscribers.
+func (m *Manager) Reload() error {
+	next, err := LoadLayered(m.args)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	before := immutableFields(m.current)
+	after := immutableFields(next)
+	for key, want := range before {
+		if got := after[key]; got != want {
+			m.mu.Unlock()
+			return fmt.Errorf("config: reload rejected, %s is immutable (was %q, got %q)", key, want, got)
+		}
+	}
+	m.current = next
+	subs := append([]func(*Config){}, m.subscribers...)
+	m.mu.Unlock()
+
+	for _, fn := range subs {
+		fn(next)
+	}
+	return nil
+}
+
+// WatchReloadSignal calls Reload every time the process receives SIGHUP,
+// sending any error Reload returns on the returned channel. The channel
+// holds one error; an error that arrives while it still holds an unread one
+// is dropped. It returns immediately; the watch runs until the process exits.
+func (m *Manager) WatchReloadSignal() <-chan error {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+
+	errs := make(chan error, 1)
+	go func() {
+		for range sighup {
+			if err := m.Reload(); err != nil {
+				select {
+				case errs <- err:
+				default:
+				}
+			}
+		}
+	}()
+	return errs
+}
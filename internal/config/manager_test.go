@@ -0,0 +1,46 @@
+package config
+
+import "testing"
+
+func TestManagerReloadRejectsImmutableFieldChange(t *testing.T) {
+	t.Setenv("HTTP_ADDR", ":8080")
+
+	m, err := NewManager(nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	before := m.Current()
+
+	t.Setenv("HTTP_ADDR", ":9090")
+	if err := m.Reload(); err == nil {
+		t.Fatal("Reload: expected an error for a changed immutable field, got nil")
+	}
+
+	if m.Current() != before {
+		t.Error("Reload: Current() changed despite the rejected reload")
+	}
+}
+
+func TestManagerReloadAppliesMutableFieldChangeAndNotifiesSubscribers(t *testing.T) {
+	t.Setenv("CACHE_LRU_SIZE", "100")
+
+	m, err := NewManager(nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+
+	var notified *Config
+	m.Subscribe(func(cfg *Config) { notified = cfg })
+
+	t.Setenv("CACHE_LRU_SIZE", "200")
+	if err := m.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+
+	if m.Current().CacheLRUSize != 200 {
+		t.Errorf("Current().CacheLRUSize = %d, want 200", m.Current().CacheLRUSize)
+	}
+	if notified == nil || notified.CacheLRUSize != 200 {
+		t.Error("Subscribe: fn was not called with the reloaded config")
+	}
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveSecretPlainLiteralPassesThrough(t *testing.T) {
+	got, err := ResolveSecret("plain-value")
+	if err != nil {
+		t.Fatalf("ResolveSecret: %v", err)
+	}
+	if got != "plain-value" {
+		t.Errorf("ResolveSecret = %q, want unchanged %q", got, "plain-value")
+	}
+}
+
+func TestResolveSecretEnvScheme(t *testing.T) {
+	t.Setenv("MY_SECRET", "super-secret")
+
+	got, err := ResolveSecret("env://MY_SECRET")
+	if err != nil {
+		t.Fatalf("ResolveSecret: %v", err)
+	}
+	if got != "super-secret" {
+		t.Errorf("ResolveSecret = %q, want %q", got, "super-secret")
+	}
+}
+
+func TestResolveSecretEnvSchemeMissingVar(t *testing.T) {
+	if _, err := ResolveSecret("env://NOT_SET_ANYWHERE"); err == nil {
+		t.Fatal("ResolveSecret: expected an error for an unset env var, got nil")
+	}
+}
+
+func TestResolveSecretFileScheme(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("file-secret\n"), 0o600); err != nil {
+		t.Fatalf("write secret file: %v", err)
+	}
+
+	got, err := ResolveSecret("file://" + path)
+	if err != nil {
+		t.Fatalf("ResolveSecret: %v", err)
+	}
+	if got != "file-secret" {
+		t.Errorf("ResolveSecret = %q, want %q (trailing newline trimmed)", got, "file-secret")
+	}
+}
+
+func TestResolveSecretUnregisteredProvider(t *testing.T) {
+	if _, err := ResolveSecret("vault://secret/openai#key"); err == nil {
+		t.Fatal("ResolveSecret: expected an error for an unregistered scheme, got nil")
+	}
+}
+
+func TestRegisterSecretProvider(t *testing.T) {
+	const scheme = "test-provider"
+	t.Cleanup(func() {
+		providersMu.Lock()
+		delete(secretProviders, scheme)
+		delete(knownSecretSchemes, scheme)
+		providersMu.Unlock()
+	})
+
+	RegisterSecretProvider(scheme, stubSecretProvider{value: "from-stub"})
+
+	got, err := ResolveSecret(scheme + "://ignored")
+	if err != nil {
+		t.Fatalf("ResolveSecret: %v", err)
+	}
+	if got != "from-stub" {
+		t.Errorf("ResolveSecret = %q, want %q", got, "from-stub")
+	}
+}
+
+type stubSecretProvider struct{ value string }
+
+func (s stubSecretProvider) Resolve(ref string) (string, error) {
+	return s.value, nil
+}
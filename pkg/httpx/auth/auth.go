@@ -0,0 +1,281 @@
+// Package auth implements OIDC-issued JWT bearer token verification for the
+// HTTP API, as an alternative to the static shared bearer token.
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/sync/singleflight"
+)
+
+// minInlineRefreshInterval bounds how often an unknown kid can trigger a
+// synchronous JWKS fetch from inside Verify. Without it, a caller sending
+// tokens with random kid values could force a JWKS fetch per request
+// (latency hit on us, request amplification against the IdP).
+const minInlineRefreshInterval = 30 * time.Second
+
+// Claims is the subset of an OIDC ID/access token that handlers care about.
+type Claims struct {
+	Subject string
+	Email   string
+	Scopes  []string
+}
+
+type contextKey string
+
+const claimsContextKey contextKey = "authClaims"
+
+// WithClaims returns a copy of ctx carrying the given claims.
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsContextKey, claims)
+}
+
+// ClaimsFromContext returns the claims attached by the OIDC middleware, if any.
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsContextKey).(*Claims)
+	return claims, ok
+}
+
+// oidcDiscovery is the subset of the OIDC discovery document we need.
+type oidcDiscovery struct {
+	JWKSURI string `json:"jwks_uri"`
+}
+
+// jwk is a single JSON Web Key from a JWKS document. Only RSA keys are
+// supported, which covers every major IdP's signing keys for ID/access tokens.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+// Verifier validates OIDC-issued JWT bearer tokens against an issuer's
+// published JWKS, refreshing the key set periodically in the background.
+type Verifier struct {
+	issuer   string
+	audience string
+	jwksURI  string
+	refresh  time.Duration
+	client   *http.Client
+
+	group singleflight.Group // coalesces concurrent refreshKeys calls
+
+	mu        sync.RWMutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+// NewVerifier discovers jwks_uri from the issuer's well-known configuration
+// and returns a Verifier ready to validate tokens for that issuer/audience.
+func NewVerifier(ctx context.Context, issuer, audience string, refresh time.Duration) (*Verifier, error) {
+	v := &Verifier{
+		issuer:   issuer,
+		audience: audience,
+		refresh:  refresh,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		keys:     map[string]*rsa.PublicKey{},
+	}
+
+	discoveryURL := strings.TrimRight(issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("auth: build discovery request: %w", err)
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("auth: fetch discovery document: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := checkStatus(resp); err != nil {
+		return nil, fmt.Errorf("auth: fetch discovery document: %w", err)
+	}
+
+	var doc oidcDiscovery
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("auth: decode discovery document: %w", err)
+	}
+	if doc.JWKSURI == "" {
+		return nil, fmt.Errorf("auth: discovery document missing jwks_uri")
+	}
+	v.jwksURI = doc.JWKSURI
+
+	if err := v.refreshKeys(ctx); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Run refreshes the JWKS on the configured interval until ctx is cancelled.
+func (v *Verifier) Run(ctx context.Context) {
+	ticker := time.NewTicker(v.refresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = v.refreshKeys(ctx)
+		}
+	}
+}
+
+func (v *Verifier) refreshKeys(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.jwksURI, nil)
+	if err != nil {
+		return fmt.Errorf("auth: build jwks request: %w", err)
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("auth: fetch jwks: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := checkStatus(resp); err != nil {
+		return fmt.Errorf("auth: fetch jwks: %w", err)
+	}
+
+	var set jwks
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("auth: decode jwks: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || k.Kid == "" {
+			continue
+		}
+		pub, err := k.rsaPublicKey()
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+
+	v.mu.Lock()
+	v.keys = keys
+	v.fetchedAt = time.Now()
+	v.mu.Unlock()
+	return nil
+}
+
+func (v *Verifier) keyByID(kid string) (*rsa.PublicKey, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	key, ok := v.keys[kid]
+	return key, ok
+}
+
+// staleSince reports how long it's been since the JWKS was last refreshed.
+func (v *Verifier) staleSince() time.Duration {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return time.Since(v.fetchedAt)
+}
+
+// checkStatus turns a non-2xx HTTP response into an error carrying the
+// status, instead of letting the caller's JSON decode fail opaquely on an
+// HTML error page.
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// Verify parses and validates tokenString against the issuer's JWKS and the
+// configured audience, returning the extracted claims on success.
+func (v *Verifier) Verify(ctx context.Context, tokenString string) (*Claims, error) {
+	parsed, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, ok := v.keyByID(kid)
+		if !ok {
+			// Key may have rotated since our last refresh; try once more,
+			// but not more than once per minInlineRefreshInterval and never
+			// more than one fetch in flight at a time — otherwise a caller
+			// sending random kid values could force a JWKS fetch per
+			// request.
+			if v.staleSince() >= minInlineRefreshInterval {
+				if _, refreshErr, _ := v.group.Do("refresh", func() (interface{}, error) {
+					// Another caller may have finished a refresh between
+					// our staleness check and this call.
+					if v.staleSince() < minInlineRefreshInterval {
+						return nil, nil
+					}
+					return nil, v.refreshKeys(ctx)
+				}); refreshErr != nil {
+					return nil, fmt.Errorf("auth: refresh keys for kid %q: %w", kid, refreshErr)
+				}
+			}
+			// Look again: a refresh (ours or a concurrent one) may have
+			// loaded kid since the first lookup.
+			key, ok = v.keyByID(kid)
+			if !ok {
+				return nil, fmt.Errorf("auth: unknown signing key %q", kid)
+			}
+		}
+		return key, nil
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512"}),
+		jwt.WithIssuer(v.issuer),
+		jwt.WithAudience(v.audience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("auth: invalid token: %w", err)
+	}
+
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok || !parsed.Valid {
+		return nil, fmt.Errorf("auth: invalid token claims")
+	}
+
+	out := &Claims{}
+	if sub, ok := claims["sub"].(string); ok {
+		out.Subject = sub
+	}
+	if email, ok := claims["email"].(string); ok {
+		out.Email = email
+	}
+	if scope, ok := claims["scope"].(string); ok && scope != "" {
+		out.Scopes = strings.Split(scope, " ")
+	}
+	return out, nil
+}
+
+// rsaPublicKey decodes the JWK's base64url-encoded modulus and exponent into
+// a standard library RSA public key.
+func (k jwk) rsaPublicKey() (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("auth: decode jwk modulus: %w", err)
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("auth: decode jwk exponent: %w", err)
+	}
+
+	e := 0
+	for _, b := range eBytes {
+		e = e<<8 | int(b)
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: e,
+	}, nil
+}
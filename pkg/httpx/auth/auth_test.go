@@ -0,0 +1,202 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func encodeJWK(t *testing.T, key *rsa.PublicKey) jwk {
+	t.Helper()
+
+	eBytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(eBytes, uint32(key.E))
+	i := 0
+	for i < len(eBytes)-1 && eBytes[i] == 0 {
+		i++
+	}
+
+	return jwk{
+		Kty: "RSA",
+		Kid: "test-key",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(eBytes[i:]),
+	}
+}
+
+func TestJWKRSAPublicKeyRoundTrip(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	k := encodeJWK(t, &priv.PublicKey)
+
+	got, err := k.rsaPublicKey()
+	if err != nil {
+		t.Fatalf("rsaPublicKey: %v", err)
+	}
+	if got.E != priv.PublicKey.E {
+		t.Errorf("E = %d, want %d", got.E, priv.PublicKey.E)
+	}
+	if got.N.Cmp(priv.PublicKey.N) != 0 {
+		t.Errorf("N = %s, want %s", got.N, priv.PublicKey.N)
+	}
+}
+
+func TestJWKRSAPublicKeyBadEncoding(t *testing.T) {
+	cases := []struct {
+		name string
+		k    jwk
+	}{
+		{"bad modulus", jwk{Kty: "RSA", Kid: "k1", N: "not-base64!!", E: "AQAB"}},
+		{"bad exponent", jwk{Kty: "RSA", Kid: "k2", N: "AQAB", E: "not-base64!!"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := tc.k.rsaPublicKey(); err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+		})
+	}
+}
+
+// signToken mints an RS256 JWT with the given kid header, signed by priv,
+// valid for the given issuer/audience/subject.
+func signToken(t *testing.T, priv *rsa.PrivateKey, kid, issuer, audience, subject string) string {
+	t.Helper()
+
+	claims := jwt.MapClaims{
+		"iss": issuer,
+		"aud": audience,
+		"sub": subject,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+
+	signed, err := token.SignedString(priv)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+func TestNewVerifierDiscoveryNon2xxStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	_, err := NewVerifier(context.Background(), srv.URL, "aud", time.Minute)
+	if err == nil {
+		t.Fatal("NewVerifier: expected an error for a non-2xx discovery response, got nil")
+	}
+}
+
+func TestNewVerifierJWKSNon2xxStatus(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"jwks_uri": srv.URL + "/jwks"})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusBadGateway)
+	})
+
+	_, err := NewVerifier(context.Background(), srv.URL, "aud", time.Minute)
+	if err == nil {
+		t.Fatal("NewVerifier: expected an error for a non-2xx jwks response, got nil")
+	}
+}
+
+// TestVerifyFloodOfUnknownKidTriggersSingleRefresh confirms that a burst of
+// requests bearing an unknown kid - e.g. right after the IdP rotates its
+// signing key - collapses into exactly one JWKS refresh, per
+// minInlineRefreshInterval and the singleflight group in Verify, instead of
+// one inline fetch per request.
+func TestVerifyFloodOfUnknownKidTriggersSingleRefresh(t *testing.T) {
+	origPriv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate orig key: %v", err)
+	}
+	rotatedPriv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rotated key: %v", err)
+	}
+	origJWK := encodeJWK(t, &origPriv.PublicKey)
+	origJWK.Kid = "orig-key"
+	rotatedJWK := encodeJWK(t, &rotatedPriv.PublicKey)
+	rotatedJWK.Kid = "rotated-key"
+
+	var jwksHits atomic.Int32
+	var rotated atomic.Bool
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"jwks_uri": srv.URL + "/jwks"})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		jwksHits.Add(1)
+		set := jwks{Keys: []jwk{origJWK}}
+		if rotated.Load() {
+			set.Keys = append(set.Keys, rotatedJWK)
+		}
+		_ = json.NewEncoder(w).Encode(set)
+	})
+
+	v, err := NewVerifier(context.Background(), srv.URL, "aud", time.Hour)
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+	if hits := jwksHits.Load(); hits != 1 {
+		t.Fatalf("jwks hits after NewVerifier = %d, want 1", hits)
+	}
+
+	// Simulate the IdP rotating its signing key, and the verifier's cache
+	// being stale enough to allow an inline refresh.
+	rotated.Store(true)
+	v.mu.Lock()
+	v.fetchedAt = time.Now().Add(-2 * minInlineRefreshInterval)
+	v.mu.Unlock()
+
+	token := signToken(t, rotatedPriv, "rotated-key", srv.URL, "aud", "user-1")
+
+	const floodSize = 20
+	var wg sync.WaitGroup
+	errs := make([]error, floodSize)
+	for i := 0; i < floodSize; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = v.Verify(context.Background(), token)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("Verify[%d]: %v", i, err)
+		}
+	}
+	if hits := jwksHits.Load(); hits != 2 {
+		t.Fatalf("jwks hits after flood = %d, want 2 (1 initial + 1 coalesced refresh)", hits)
+	}
+}
@@ -0,0 +1,150 @@
+package httpx
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig describes a CORS policy. A CORSConfig matches origins only once
+// compiled by NewCORSConfig (which (*config.Config).CORSConfig calls with the
+// CORS_* fields); one built as a plain struct literal allows no origin.
+type CORSConfig struct {
+	// AllowedOrigins entries are matched exactly, except ones prefixed
+	// "regex:", which are compiled and matched against the full Origin
+	// header.
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+
+	origins []originMatcher
+}
+
+type originMatcher interface {
+	match(origin string) bool
+}
+
+type exactOrigin string
+
+func (e exactOrigin) match(origin string) bool { return string(e) == origin }
+
+type regexOrigin struct{ re *regexp.Regexp }
+
+func (r regexOrigin) match(origin string) bool { return r.re.MatchString(origin) }
+
+// NewCORSConfig compiles cfg into a CORSConfig, returning an error if any
+// "regex:" origin entry fails to compile.
+func NewCORSConfig(cfg CORSConfig) (*CORSConfig, error) {
+	out := cfg
+	out.origins = make([]originMatcher, 0, len(cfg.AllowedOrigins))
+	for _, o := range cfg.AllowedOrigins {
+		if pattern, ok := strings.CutPrefix(o, "regex:"); ok {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("cors: compile origin pattern %q: %w", pattern, err)
+			}
+			out.origins = append(out.origins, regexOrigin{re})
+			continue
+		}
+		out.origins = append(out.origins, exactOrigin(o))
+	}
+	return &out, nil
+}
+
+func (c *CORSConfig) allows(origin string) bool {
+	for _, m := range c.origins {
+		if m.match(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// CORSMiddlewareFromConfig enforces cfg's policy: it only ever reflects a
+// whitelisted Origin (never "*"), always sets Vary: Origin, and on preflight
+// echoes only the whitelisted subset of Access-Control-Request-Headers.
+func CORSMiddlewareFromConfig(cfg *CORSConfig) func(http.Handler) http.Handler {
+	allowedHeaders := make(map[string]string, len(cfg.AllowedHeaders)) // lower -> canonical
+	for _, h := range cfg.AllowedHeaders {
+		allowedHeaders[strings.ToLower(h)] = h
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Origin")
+
+			origin := r.Header.Get("Origin")
+			if origin == "" || !cfg.allows(origin) {
+				if r.Method == http.MethodOptions {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			if cfg.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+			if len(cfg.ExposedHeaders) > 0 {
+				w.Header().Set("Access-Control-Expose-Headers", strings.Join(cfg.ExposedHeaders, ", "))
+			}
+
+			if r.Method != http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(cfg.AllowedMethods, ", "))
+			if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+				w.Header().Add("Vary", "Access-Control-Request-Headers")
+				w.Header().Set("Access-Control-Allow-Headers", echoAllowedHeaders(requested, allowedHeaders))
+			}
+			if cfg.MaxAge > 0 {
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(cfg.MaxAge.Seconds())))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// echoAllowedHeaders returns the subset of the comma-separated requested
+// headers that are present (case-insensitively) in allowed, preserving
+// allowed's canonical casing.
+func echoAllowedHeaders(requested string, allowed map[string]string) string {
+	var out []string
+	for _, h := range strings.Split(requested, ",") {
+		h = strings.TrimSpace(h)
+		if canonical, ok := allowed[strings.ToLower(h)]; ok {
+			out = append(out, canonical)
+		}
+	}
+	return strings.Join(out, ", ")
+}
+
+// CORSMiddleware adds permissive CORS headers for every origin.
+//
+// Deprecated: use CORSMiddlewareFromConfig with an explicit allowlist.
+// Access-Control-Allow-Origin: * cannot be combined with credentialed
+// requests and is unsafe once auth is enabled.
+func CORSMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,105 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEchoAllowedHeaders(t *testing.T) {
+	allowed := map[string]string{
+		"content-type":  "Content-Type",
+		"authorization": "Authorization",
+	}
+
+	cases := []struct {
+		name      string
+		requested string
+		want      string
+	}{
+		{"single allowed header", "content-type", "Content-Type"},
+		{"case-insensitive match", "Content-Type, AUTHORIZATION", "Content-Type, Authorization"},
+		{"disallowed header dropped", "content-type, x-evil", "Content-Type"},
+		{"all disallowed", "x-evil, x-also-evil", ""},
+		{"whitespace tolerated", "  content-type ,  authorization ", "Content-Type, Authorization"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := echoAllowedHeaders(tc.requested, allowed); got != tc.want {
+				t.Errorf("echoAllowedHeaders(%q) = %q, want %q", tc.requested, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCORSMiddlewareFromConfig(t *testing.T) {
+	cfg, err := NewCORSConfig(CORSConfig{
+		AllowedOrigins: []string{"https://example.com", "regex:^https://.*\\.example\\.org$"},
+		AllowedMethods: []string{"GET", "POST"},
+		AllowedHeaders: []string{"Content-Type", "Authorization"},
+	})
+	if err != nil {
+		t.Fatalf("NewCORSConfig: %v", err)
+	}
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := CORSMiddlewareFromConfig(cfg)(ok)
+
+	t.Run("disallowed origin gets no CORS headers", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/check", nil)
+		req.Header.Set("Origin", "https://evil.example")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Errorf("Access-Control-Allow-Origin = %q, want empty", got)
+		}
+		if got := rec.Header().Get("Vary"); got != "Origin" {
+			t.Errorf("Vary = %q, want %q", got, "Origin")
+		}
+	})
+
+	t.Run("allowed exact origin is reflected, never wildcard", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/check", nil)
+		req.Header.Set("Origin", "https://example.com")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://example.com" {
+			t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, "https://example.com")
+		}
+	})
+
+	t.Run("allowed regex origin matches", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/check", nil)
+		req.Header.Set("Origin", "https://tenant.example.org")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://tenant.example.org" {
+			t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, "https://tenant.example.org")
+		}
+	})
+
+	t.Run("preflight echoes only whitelisted request headers", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodOptions, "/v1/check", nil)
+		req.Header.Set("Origin", "https://example.com")
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		req.Header.Set("Access-Control-Request-Headers", "Content-Type, X-Evil")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusNoContent)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Headers"); got != "Content-Type" {
+			t.Errorf("Access-Control-Allow-Headers = %q, want %q", got, "Content-Type")
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Methods"); got != "GET, POST" {
+			t.Errorf("Access-Control-Allow-Methods = %q, want %q", got, "GET, POST")
+		}
+	})
+}
@@ -0,0 +1,189 @@
+// Package limits implements admission control for the HTTP API: a global
+// cap on requests in flight and a per-caller token-bucket rate limit.
+package limits
+
+import (
+	"net"
+	"net/http"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+
+	"github.com/TimAnthonyAlexander/realitycheck/pkg/httpx/auth"
+)
+
+// Config controls the admission control middleware.
+type Config struct {
+	// MaxInFlight is the global cap on concurrently admitted requests.
+	// Zero means unlimited.
+	MaxInFlight int
+
+	// PerClientRPS and PerClientBurst size the per-caller token bucket.
+	// Zero RPS means unlimited.
+	PerClientRPS   float64
+	PerClientBurst int
+
+	// LongRunningPath matches request paths (e.g. streaming/analysis
+	// endpoints) that are rate limited but excluded from the in-flight cap,
+	// since they're expected to hold a slot for a long time.
+	LongRunningPath *regexp.Regexp
+
+	// ClientTTL is how long an idle per-client limiter is kept before being
+	// evicted. Defaults to 10 minutes.
+	ClientTTL time.Duration
+}
+
+var (
+	acceptedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "realitycheck",
+		Subsystem: "http_limits",
+		Name:      "accepted_total",
+		Help:      "Requests admitted by the rate/in-flight limiter.",
+	})
+	rejectedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "realitycheck",
+		Subsystem: "http_limits",
+		Name:      "rejected_total",
+		Help:      "Requests rejected by the rate/in-flight limiter, by reason.",
+	}, []string{"reason"})
+	queuedGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "realitycheck",
+		Subsystem: "http_limits",
+		Name:      "in_flight",
+		Help:      "Requests currently counted against the in-flight budget.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(acceptedTotal, rejectedTotal, queuedGauge)
+}
+
+// Limiter enforces a Config across all requests passed through its
+// Middleware.
+type Limiter struct {
+	cfg     Config
+	slots   chan struct{}
+	clients sync.Map // string -> *clientLimiter
+}
+
+type clientLimiter struct {
+	limiter *rate.Limiter
+
+	// lastSeenUnixNano is read and written from concurrent requests sharing
+	// the same caller key, plus the eviction goroutine, so it's an atomic
+	// rather than a plain time.Time.
+	lastSeenUnixNano atomic.Int64
+}
+
+func (cl *clientLimiter) touch(now time.Time) {
+	cl.lastSeenUnixNano.Store(now.UnixNano())
+}
+
+func (cl *clientLimiter) idleFor(now time.Time) time.Duration {
+	return now.Sub(time.Unix(0, cl.lastSeenUnixNano.Load()))
+}
+
+// New builds a Limiter from cfg. Call Middleware to get the http middleware.
+func New(cfg Config) *Limiter {
+	if cfg.ClientTTL == 0 {
+		cfg.ClientTTL = 10 * time.Minute
+	}
+
+	l := &Limiter{cfg: cfg}
+	if cfg.MaxInFlight > 0 {
+		l.slots = make(chan struct{}, cfg.MaxInFlight)
+	}
+	return l
+}
+
+// Middleware returns the admission control middleware built from l's Config.
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !l.allowRate(r) {
+			rejectedTotal.WithLabelValues("rate_limited").Inc()
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, `{"code":"rate_limited","message":"too many requests"}`, http.StatusTooManyRequests)
+			return
+		}
+
+		longRunning := l.cfg.LongRunningPath != nil && l.cfg.LongRunningPath.MatchString(r.URL.Path)
+		if longRunning || l.slots == nil {
+			acceptedTotal.Inc()
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		select {
+		case l.slots <- struct{}{}:
+			queuedGauge.Inc()
+			acceptedTotal.Inc()
+			defer func() {
+				<-l.slots
+				queuedGauge.Dec()
+			}()
+			next.ServeHTTP(w, r)
+		default:
+			rejectedTotal.WithLabelValues("in_flight_exceeded").Inc()
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, `{"code":"overloaded","message":"server at capacity"}`, http.StatusServiceUnavailable)
+		}
+	})
+}
+
+func (l *Limiter) allowRate(r *http.Request) bool {
+	if l.cfg.PerClientRPS <= 0 {
+		return true
+	}
+	return l.limiterFor(callerKey(r)).Allow()
+}
+
+func (l *Limiter) limiterFor(key string) *rate.Limiter {
+	now := time.Now()
+	if v, ok := l.clients.Load(key); ok {
+		cl := v.(*clientLimiter)
+		cl.touch(now)
+		return cl.limiter
+	}
+
+	cl := &clientLimiter{
+		limiter: rate.NewLimiter(rate.Limit(l.cfg.PerClientRPS), l.cfg.PerClientBurst),
+	}
+	cl.touch(now)
+	actual, loaded := l.clients.LoadOrStore(key, cl)
+	if loaded {
+		actual.(*clientLimiter).touch(now)
+		return actual.(*clientLimiter).limiter
+	}
+	go l.evictEventually(key, cl)
+	return cl.limiter
+}
+
+// evictEventually removes key's limiter once it's been idle past ClientTTL,
+// so long-lived deployments don't accumulate one limiter per IP forever.
+func (l *Limiter) evictEventually(key string, cl *clientLimiter) {
+	ticker := time.NewTicker(l.cfg.ClientTTL)
+	defer ticker.Stop()
+	for range ticker.C {
+		if cl.idleFor(time.Now()) >= l.cfg.ClientTTL {
+			l.clients.Delete(key)
+			return
+		}
+	}
+}
+
+// callerKey identifies the caller for rate limiting: the authenticated
+// subject if OIDC auth ran, otherwise the client IP.
+func callerKey(r *http.Request) string {
+	if claims, ok := auth.ClaimsFromContext(r.Context()); ok && claims.Subject != "" {
+		return "sub:" + claims.Subject
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
@@ -1,12 +1,25 @@
 package httpx
 
 import (
+	"log/slog"
 	"net/http"
 	"strings"
 	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/TimAnthonyAlexander/realitycheck/pkg/httpx/auth"
 )
 
-// AuthMiddleware provides bearer token authentication
+// AuthMiddleware provides static bearer token authentication. An empty
+// bearerToken lets every request through.
+//
+// Deprecated: use OIDCAuthMiddleware for OIDC-issued JWTs. OIDCAuthMiddleware
+// does not accept the static bearer token and does not let requests without
+// a token through.
 func AuthMiddleware(bearerToken string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -16,63 +29,112 @@ func AuthMiddleware(bearerToken string) func(http.Handler) http.Handler {
 				return
 			}
 
-			auth := r.Header.Get("Authorization")
-			if auth == "" {
-				http.Error(w, "Authorization header required", http.StatusUnauthorized)
+			token, ok := bearerFromHeader(r)
+			if !ok {
+				http.Error(w, "Bearer token required", http.StatusUnauthorized)
+				return
+			}
+			if token != bearerToken {
+				http.Error(w, "Invalid bearer token", http.StatusUnauthorized)
 				return
 			}
 
-			if !strings.HasPrefix(auth, "Bearer ") {
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// OIDCAuthMiddleware authenticates requests against an OIDC issuer's JWKS,
+// attaching the parsed claims to the request context on success. Unlike
+// AuthMiddleware it never falls back to "open" on a missing token, since an
+// OIDC deployment always expects an identity.
+func OIDCAuthMiddleware(verifier *auth.Verifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := bearerFromHeader(r)
+			if !ok {
 				http.Error(w, "Bearer token required", http.StatusUnauthorized)
 				return
 			}
 
-			token := strings.TrimPrefix(auth, "Bearer ")
-			if token != bearerToken {
+			claims, err := verifier.Verify(r.Context(), token)
+			if err != nil {
 				http.Error(w, "Invalid bearer token", http.StatusUnauthorized)
 				return
 			}
 
-			next.ServeHTTP(w, r)
+			next.ServeHTTP(w, r.WithContext(auth.WithClaims(r.Context(), claims)))
+		})
+	}
+}
+
+// bearerFromHeader extracts the token from a well-formed Authorization header.
+func bearerFromHeader(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return "", false
+	}
+	return strings.TrimPrefix(header, "Bearer "), true
+}
+
+// LoggingMiddleware logs HTTP requests through logger, one structured
+// "http request" record per request with method/path/status/duration.
+func LoggingMiddleware(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			// Create a response writer that captures status code
+			rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+			next.ServeHTTP(rw, r)
+
+			logger.InfoContext(r.Context(), "http request",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", rw.statusCode,
+				"duration", time.Since(start),
+			)
 		})
 	}
 }
 
-// CORSMiddleware adds CORS headers
-func CORSMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+// TracingMiddleware starts an OpenTelemetry server span per request, using
+// the W3C traceparent header (if present) to continue an upstream trace,
+// and records status/duration/route as span attributes.
+func TracingMiddleware(tracer trace.Tracer) func(http.Handler) http.Handler {
+	propagator := propagation.TraceContext{}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+			ctx, span := tracer.Start(ctx, r.Method+" "+r.URL.Path,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.method", r.Method),
+					attribute.String("http.route", r.URL.Path),
+				),
+			)
+			defer span.End()
+
+			start := time.Now()
+			rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
+			next.ServeHTTP(rw, r.WithContext(ctx))
 
-		next.ServeHTTP(w, r)
-	})
+			span.SetAttributes(
+				attribute.Int("http.status_code", rw.statusCode),
+				attribute.Int64("http.duration_ms", time.Since(start).Milliseconds()),
+			)
+		})
+	}
 }
 
-// LoggingMiddleware logs HTTP requests
-func LoggingMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-
-		// Create a response writer that captures status code
-		rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
-
-		next.ServeHTTP(rw, r)
-
-		duration := time.Since(start)
-		// In a real implementation, use a proper logger
-		println(
-			r.Method,
-			r.URL.Path,
-			rw.statusCode,
-			duration.String(),
-		)
-	})
+// NewTracer returns a tracer for serviceName from the globally configured
+// OpenTelemetry TracerProvider (set with otel.SetTracerProvider); until one
+// is set, the tracer is otel's default no-op.
+func NewTracer(serviceName string) trace.Tracer {
+	return otel.Tracer(serviceName)
 }
 
 // responseWriter wraps http.ResponseWriter to capture status code
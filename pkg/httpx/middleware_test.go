@@ -0,0 +1,90 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestLoggingMiddlewareLogsOneRecordWithStatusAndDuration(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	handler := LoggingMiddleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/check", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	out := buf.String()
+	if n := bytes.Count(buf.Bytes(), []byte(`"msg":"http request"`)); n != 1 {
+		t.Fatalf("got %d \"http request\" records, want exactly 1; log: %s", n, out)
+	}
+	for _, want := range []string{
+		`"method":"GET"`,
+		`"path":"/v1/check"`,
+		`"status":418`,
+		`"duration":`,
+	} {
+		if !bytes.Contains(buf.Bytes(), []byte(want)) {
+			t.Errorf("log record missing %s; log: %s", want, out)
+		}
+	}
+}
+
+func TestTracingMiddlewareRecordsSpanNameAndAttributes(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer func() { _ = tp.Shutdown(context.Background()) }()
+	tracer := tp.Tracer("test")
+
+	handler := TracingMiddleware(tracer)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/analyze", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+
+	if want := "POST /v1/analyze"; span.Name != want {
+		t.Errorf("span name = %q, want %q", span.Name, want)
+	}
+
+	attrs := map[string]bool{}
+	for _, kv := range span.Attributes {
+		attrs[string(kv.Key)] = true
+		switch kv.Key {
+		case "http.method":
+			if got := kv.Value.AsString(); got != http.MethodPost {
+				t.Errorf("http.method = %q, want %q", got, http.MethodPost)
+			}
+		case "http.route":
+			if got := kv.Value.AsString(); got != "/v1/analyze" {
+				t.Errorf("http.route = %q, want %q", got, "/v1/analyze")
+			}
+		case "http.status_code":
+			if got := kv.Value.AsInt64(); got != http.StatusCreated {
+				t.Errorf("http.status_code = %d, want %d", got, http.StatusCreated)
+			}
+		}
+	}
+	for _, want := range []string{"http.method", "http.route", "http.status_code", "http.duration_ms"} {
+		if !attrs[want] {
+			t.Errorf("span missing attribute %q", want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Server owns an http.Server and shuts it down gracefully on SIGTERM/SIGINT,
+// giving in-flight requests up to the shutdown grace period passed to
+// NewServer to finish before the connections are forcibly closed.
+type Server struct {
+	httpServer    *http.Server
+	shutdownGrace time.Duration
+}
+
+// NewServer builds a Server listening on addr with handler, draining
+// in-flight requests for up to shutdownGrace on shutdown.
+func NewServer(addr string, handler http.Handler, shutdownGrace time.Duration) *Server {
+	return &Server{
+		httpServer:    &http.Server{Addr: addr, Handler: handler},
+		shutdownGrace: shutdownGrace,
+	}
+}
+
+// Run starts the server and blocks until it receives SIGTERM/SIGINT, at
+// which point it stops accepting new connections and waits up to the
+// shutdown grace period for in-flight requests (including long-running
+// analysis jobs) to finish. If the server fails to serve before a signal
+// arrives, Run returns that error.
+func (s *Server) Run() error {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(sig)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+			return
+		}
+		serveErr <- nil
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-sig:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownGrace)
+	defer cancel()
+	return s.httpServer.Shutdown(ctx)
+}
@@ -0,0 +1,154 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// TimeoutMiddleware bounds each request to d by cancelling its
+// context.Context when the deadline passes, so downstream OpenAI calls, DB
+// queries, and analysis loops that respect ctx abort promptly. On timeout it
+// writes a structured JSON error instead of a plain string.
+//
+// Handlers must observe ctx.Done() (via r.Context()) and return once it
+// fires. TimeoutMiddleware does not wait for the handler goroutine after a
+// timeout — doing so would hold the client's connection open for as long as
+// a non-cooperative handler keeps running, defeating the point of a
+// timeout — so a handler that ignores cancellation will keep running in the
+// background until it eventually returns. Its writes never reach the real
+// http.ResponseWriter once the timeout fires: like the stdlib's
+// http.TimeoutHandler, timeoutWriter buffers them instead, so the handler
+// goroutine can never write to the real ResponseWriter after ServeHTTP has
+// returned to net/http.
+func TimeoutMiddleware(d time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+
+			tw := newTimeoutWriter()
+			done := make(chan struct{})
+
+			go func() {
+				defer close(done)
+				next.ServeHTTP(tw, r.WithContext(ctx))
+			}()
+
+			select {
+			case <-done:
+				tw.flush(w)
+			case <-ctx.Done():
+				tw.writeTimeout(w)
+				// Deliberately not waiting on done: see the doc comment
+				// above. The handler goroutine may still be writing to tw,
+				// but tw only ever buffers internally from here on, so none
+				// of it reaches w after this function returns.
+			}
+		})
+	}
+}
+
+// WithTimeout wraps handler with its own timeout d. Under a router-wide
+// TimeoutMiddleware the earlier of the two deadlines applies: a route
+// wrapped this way can get a shorter deadline than the router's, not a
+// longer one.
+func WithTimeout(handler http.Handler, d time.Duration) http.Handler {
+	return TimeoutMiddleware(d)(handler)
+}
+
+// timeoutWriter is an http.ResponseWriter that buffers the handler's header,
+// status code, and body entirely in memory instead of writing to a real
+// ResponseWriter. Only the goroutine running TimeoutMiddleware's select ever
+// touches the real http.ResponseWriter, by calling flush or writeTimeout
+// exactly once; the handler goroutine — which may keep running past that
+// point — only ever reaches this buffer, so it can never write to the real
+// ResponseWriter after net/http considers the request finished. This
+// mirrors how the stdlib's http.TimeoutHandler uses its own wbuf.
+type timeoutWriter struct {
+	mu          sync.Mutex
+	header      http.Header
+	body        bytes.Buffer
+	code        int
+	wroteHeader bool
+	timedOut    bool
+}
+
+func newTimeoutWriter() *timeoutWriter {
+	return &timeoutWriter{header: make(http.Header), code: http.StatusOK}
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	return tw.header
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader = true
+	tw.code = code
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return len(b), nil
+	}
+	tw.wroteHeader = true
+	return tw.body.Write(b)
+}
+
+// flush copies the buffered response into the real ResponseWriter. Called
+// once, by TimeoutMiddleware's select goroutine, after the handler has
+// returned.
+func (tw *timeoutWriter) flush(w http.ResponseWriter) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	copyHeader(w.Header(), tw.header)
+	w.WriteHeader(tw.code)
+	_, _ = w.Write(tw.body.Bytes())
+}
+
+// writeTimeout marks tw as timed out, discarding any response the handler
+// goroutine buffers from this point on, and writes the timeout response to
+// the real ResponseWriter. Called once, by TimeoutMiddleware's select
+// goroutine, the moment ctx's deadline fires.
+func (tw *timeoutWriter) writeTimeout(w http.ResponseWriter) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.wroteHeader {
+		// The handler already committed a response before the deadline
+		// fired; let it stand rather than overwrite it. It was buffered,
+		// so flush it now that we own w.
+		copyHeader(w.Header(), tw.header)
+		w.WriteHeader(tw.code)
+		_, _ = w.Write(tw.body.Bytes())
+		tw.timedOut = true
+		return
+	}
+	tw.timedOut = true
+	tw.wroteHeader = true
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusGatewayTimeout)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"code":    "timeout",
+		"message": "request exceeded its deadline",
+	})
+}
+
+func copyHeader(dst, src http.Header) {
+	for k, values := range src {
+		for _, v := range values {
+			dst.Add(k, v)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTimeoutMiddlewareReturnsPromptlyForNonCooperativeHandler(t *testing.T) {
+	unblock := make(chan struct{})
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Deliberately ignores r.Context() cancellation, mimicking a
+		// handler that doesn't check ctx.Done().
+		<-unblock
+		w.Header().Set("X-Late", "yes")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("too late"))
+	})
+	defer close(unblock)
+
+	handler := TimeoutMiddleware(20 * time.Millisecond)(slow)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/analyze", nil)
+	rec := httptest.NewRecorder()
+
+	start := time.Now()
+	handler.ServeHTTP(rec, req)
+	elapsed := time.Since(start)
+
+	if elapsed > 2*time.Second {
+		t.Fatalf("ServeHTTP blocked for %s, want it to return promptly at the deadline", elapsed)
+	}
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusGatewayTimeout)
+	}
+
+	var body map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body["code"] != "timeout" {
+		t.Errorf(`body["code"] = %q, want "timeout"`, body["code"])
+	}
+}
+
+func TestTimeoutMiddlewareHandlerThatWroteHeaderBeforeDeadlineKeepsWriting(t *testing.T) {
+	unblock := make(chan struct{})
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Claims the response before the deadline, like a streaming
+		// handler, then ignores r.Context() cancellation and keeps writing
+		// well past it.
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("partial"))
+		<-unblock
+		_, _ = w.Write([]byte(" too late"))
+	})
+	defer close(unblock)
+
+	handler := TimeoutMiddleware(20 * time.Millisecond)(slow)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/stream", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := rec.Body.String(); got != "partial" {
+		t.Fatalf("body = %q, want %q (the post-deadline write must never reach the real ResponseWriter)", got, "partial")
+	}
+}
+
+func TestTimeoutMiddlewareFastHandlerIsUnaffected(t *testing.T) {
+	fast := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Fast", "yes")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("ok"))
+	})
+
+	handler := TimeoutMiddleware(time.Second)(fast)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/check", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if got := rec.Header().Get("X-Fast"); got != "yes" {
+		t.Errorf("X-Fast header = %q, want %q", got, "yes")
+	}
+	if rec.Body.String() != "ok" {
+		t.Errorf("body = %q, want %q", rec.Body.String(), "ok")
+	}
+}